@@ -0,0 +1,94 @@
+package gateway
+
+import (
+	"encoding/json"
+	"mime"
+	"strings"
+)
+
+// Codec encodes and decodes request and response bodies for a particular
+// Content-Type. Register additional codecs (protobuf, msgpack, ...) on
+// Config.Codecs to negotiate them alongside the built-in JSON codec.
+type Codec interface {
+	// Marshal encodes v.
+	Marshal(v interface{}) ([]byte, error)
+
+	// Unmarshal decodes data into v.
+	Unmarshal(data []byte, v interface{}) error
+
+	// ContentType is the canonical Content-Type this codec produces.
+	ContentType() string
+
+	// Accepts reports whether this codec can satisfy the given Accept header value.
+	Accepts(accept string) bool
+}
+
+// encodedBody marks a Response.Body as already encoded by a negotiated Codec,
+// so later stages (e.g. the proxy response conversion) use it verbatim
+// instead of JSON-encoding it again.
+type encodedBody string
+
+// jsonCodec is the default Codec, used when no Content-Type / Accept header
+// matches a codec registered on Config.Codecs.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (jsonCodec) Accepts(accept string) bool {
+	return accept == "" || strings.Contains(accept, "application/json") || strings.Contains(accept, "*/*")
+}
+
+// codecs returns the codecs configured on g, with the default JSON codec
+// appended as a fallback.
+func (g *Gateway) codecs() []Codec {
+	codecs := make([]Codec, 0, len(g.Codecs)+1)
+	codecs = append(codecs, g.Codecs...)
+	return append(codecs, jsonCodec{})
+}
+
+// requestCodec selects the Codec to decode the request body with, matching
+// `contentType` (the request's Content-Type header) against each registered
+// codec, falling back to JSON.
+func (g *Gateway) requestCodec(contentType string) Codec {
+	contentType = mediaType(contentType)
+
+	for _, c := range g.codecs() {
+		if mediaType(c.ContentType()) == contentType {
+			return c
+		}
+	}
+
+	return jsonCodec{}
+}
+
+// responseCodec selects the Codec to encode the response body with, matching
+// `accept` (the request's Accept header) against each registered codec,
+// falling back to JSON.
+func (g *Gateway) responseCodec(accept string) Codec {
+	for _, c := range g.codecs() {
+		if c.Accepts(accept) {
+			return c
+		}
+	}
+
+	return jsonCodec{}
+}
+
+// mediaType strips parameters (e.g. "; charset=utf-8") from a Content-Type value.
+func mediaType(s string) string {
+	t, _, err := mime.ParseMediaType(s)
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+	return t
+}
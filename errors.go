@@ -0,0 +1,91 @@
+package gateway
+
+// Responder is an interface allowing you to customize the HTTP response.
+type Responder interface {
+	Status() int
+	Body() interface{}
+	Headers() map[string]string
+}
+
+// legacyResponder is the original two-method Responder contract. It's kept
+// so existing implementations that predate Headers() keep working.
+type legacyResponder interface {
+	Status() int
+	Body() interface{}
+}
+
+// legacyResponderAdapter adapts a legacyResponder to Responder, reporting no
+// headers.
+type legacyResponderAdapter struct {
+	legacyResponder
+}
+
+func (legacyResponderAdapter) Headers() map[string]string {
+	return nil
+}
+
+// asResponder reports whether `v` implements Responder (or the older
+// two-method contract), returning it adapted to Responder.
+func asResponder(v interface{}) (Responder, bool) {
+	if r, ok := v.(Responder); ok {
+		return r, true
+	}
+	if r, ok := v.(legacyResponder); ok {
+		return legacyResponderAdapter{r}, true
+	}
+	return nil, false
+}
+
+// HTTPError is a concrete Responder for returning typed error responses from
+// handler methods, via NewError.
+type HTTPError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// NewError returns an *HTTPError responding with `status`, with a body of
+// `{"error": code, "message": message}`.
+func NewError(status int, code, message string) *HTTPError {
+	return &HTTPError{StatusCode: status, Code: code, Message: message}
+}
+
+// Error implements error.
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+// Status implements Responder.
+func (e *HTTPError) Status() int {
+	return e.StatusCode
+}
+
+// Body implements Responder.
+func (e *HTTPError) Body() interface{} {
+	return struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}{e.Code, e.Message}
+}
+
+// Headers implements Responder.
+func (e *HTTPError) Headers() map[string]string {
+	return nil
+}
+
+// responseForError turns a handler error into a Response: via Responder (or
+// the legacy two-method contract) if it implements one, else via
+// Config.ErrorMapper if configured, else a generic 500.
+func (g *Gateway) responseForError(err error) *Response {
+	if r, ok := asResponder(err); ok {
+		return &Response{Status: r.Status(), Body: r.Body(), Headers: r.Headers()}
+	}
+
+	if g.ErrorMapper != nil {
+		if r := g.ErrorMapper(err); r != nil {
+			return &Response{Status: r.Status(), Body: r.Body(), Headers: r.Headers()}
+		}
+	}
+
+	return &Response{Status: 500, Body: "Internal Server Error"}
+}
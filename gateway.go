@@ -3,10 +3,12 @@
 package gateway
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
 	"reflect"
+	"strings"
 
 	"github.com/apex/go-apex"
 	"github.com/zhgo/nameconv"
@@ -15,10 +17,34 @@ import (
 // error interface type.
 var errType = reflect.TypeOf((*error)(nil)).Elem()
 
-// Responder is an interface allowing you to customize the HTTP response.
-type Responder interface {
-	Status() int
-	Body() interface{}
+// context key type, unexported to avoid collisions with other packages.
+type contextKey int
+
+// context keys.
+const (
+	requestContextKey contextKey = iota
+	methodContextKey
+)
+
+// Handler handles a decoded request, returning the response to send back.
+type Handler func(context.Context, *Request) (*Response, error)
+
+// Middleware wraps a Handler to add cross-cutting behavior (auth, logging,
+// metrics, panic recovery, rate limiting, etc). A Middleware may short-circuit
+// the chain by returning a *Response without calling the wrapped Handler.
+type Middleware func(Handler) Handler
+
+// RequestFromContext returns the *Request associated with ctx, or nil.
+func RequestFromContext(ctx context.Context) *Request {
+	req, _ := ctx.Value(requestContextKey).(*Request)
+	return req
+}
+
+// MethodFromContext returns the resolved *reflect.Method associated with ctx,
+// or nil if no method was matched.
+func MethodFromContext(ctx context.Context) *reflect.Method {
+	method, _ := ctx.Value(methodContextKey).(*reflect.Method)
+	return method
 }
 
 // Context metadata.
@@ -46,6 +72,17 @@ type Context struct {
 // Header fields.
 type Header map[string]string
 
+// Get returns the header value for `name`, matching case-insensitively, or
+// "" if not present.
+func (h Header) Get(name string) string {
+	for k, v := range h {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
 // Request from API Gateway requests.
 type Request struct {
 	Body   json.RawMessage `json:"body"` // Body of the request
@@ -60,8 +97,9 @@ type Request struct {
 
 // Response for API Gateway requests.
 type Response struct {
-	Status int         `json:"status"`
-	Body   interface{} `json:"body"`
+	Status  int               `json:"status"`
+	Body    interface{}       `json:"body"`
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
 // Gateway wraps your service to expose its methods.
@@ -72,10 +110,36 @@ type Gateway struct {
 
 // Config for the gateway service.
 type Config struct {
-	Service interface{} // Service instance
-	Verbose bool        // Verbose logging
+	Service     interface{}  // Service instance
+	Verbose     bool         // Verbose logging
+	Middleware  []Middleware // Middleware chain applied to every request
+	EventFormat EventFormat  // Event format, default FormatAuto
+	PathParam   string       // Path variable holding the RPC method, default "method"
+	Codecs      []Codec      // Additional codecs, negotiated alongside the default JSON codec
+
+	DisableIntrospection bool              // Disable the IntrospectionMethod
+	MethodDoc            map[string]string // Doc strings for methods, by name, surfaced via IntrospectionMethod
+
+	Validator Validator // Input validator, default validates `validate` struct tags
+
+	ErrorMapper func(error) Responder // Maps a non-Responder error to a Responder, e.g. sql.ErrNoRows to 404
 }
 
+// EventFormat identifies the shape of the incoming Lambda event.
+type EventFormat int
+
+const (
+	// FormatAuto detects the event format from the keys present in the raw event.
+	FormatAuto EventFormat = iota
+
+	// FormatPassthrough is the "method request passthrough" template format,
+	// with `params.path.method` and a parsed `context`.
+	FormatPassthrough
+
+	// FormatProxy is the API Gateway (or ALB) Lambda Proxy Integration event format.
+	FormatProxy
+)
+
 // New returns a new gateway with `service`.
 func New(service interface{}) *Gateway {
 	return NewConfig(&Config{
@@ -94,6 +158,11 @@ func NewConfig(config *Config) *Gateway {
 	return g
 }
 
+// Use appends middleware to the chain, in the order they should run.
+func (g *Gateway) Use(middleware ...Middleware) {
+	g.Middleware = append(g.Middleware, middleware...)
+}
+
 // log when Verbose is enabled.
 func (g *Gateway) log(s string, v ...interface{}) {
 	if g.Verbose {
@@ -133,17 +202,81 @@ func (g *Gateway) Lookup(name string) *reflect.Method {
 
 // Handle Lambda event.
 func (g *Gateway) Handle(event json.RawMessage, ctx *apex.Context) (interface{}, error) {
-	var req Request
-
-	if err := json.Unmarshal(event, &req); err != nil {
-		return &Response{http.StatusBadRequest, "Malformed Request"}, nil
+	req, proxy, err := g.decodeRequest(event)
+	if err != nil {
+		res := &Response{Status: http.StatusBadRequest, Body: "Malformed Request"}
+		if proxy {
+			return res.toProxy(), nil
+		}
+		return res, nil
 	}
 
 	// lookup method
-	name := req.Params.Path.Method
-	method := g.Lookup(name)
+	method := g.Lookup(req.Params.Path.Method)
+
+	c := context.WithValue(context.Background(), requestContextKey, req)
+	if method != nil {
+		c = context.WithValue(c, methodContextKey, method)
+	}
+
+	res, err := g.chain()(c, req)
+	if err != nil {
+		return nil, err
+	}
+
+	g.negotiateResponseCodec(req, res)
+
+	if !proxy {
+		return res, nil
+	}
+
+	return res.toProxy(), nil
+}
+
+// negotiateResponseCodec selects a Codec using the request's Accept header
+// and, if it differs from the default JSON codec, re-encodes `res.Body` and
+// sets the response Content-Type. The default JSON codec is left untouched to
+// preserve the existing passthrough behavior of returning native Go values.
+func (g *Gateway) negotiateResponseCodec(req *Request, res *Response) {
+	codec := g.responseCodec(req.Params.Header.Get("Accept"))
+	if _, ok := codec.(jsonCodec); ok || res.Body == nil {
+		return
+	}
+
+	body, err := codec.Marshal(res.Body)
+	if err != nil {
+		return
+	}
+
+	res.Body = encodedBody(body)
+	if res.Headers == nil {
+		res.Headers = map[string]string{}
+	}
+	if _, ok := res.Headers["Content-Type"]; !ok {
+		res.Headers["Content-Type"] = codec.ContentType()
+	}
+}
+
+// chain builds the configured middleware around method dispatch, the
+// innermost handler.
+func (g *Gateway) chain() Handler {
+	h := g.dispatch
+	for i := len(g.Middleware) - 1; i >= 0; i-- {
+		h = g.Middleware[i](h)
+	}
+	return h
+}
+
+// dispatch invokes the method resolved onto ctx, decoding `req.Body` into its
+// input and translating its return values into a Response.
+func (g *Gateway) dispatch(ctx context.Context, req *Request) (*Response, error) {
+	if !g.DisableIntrospection && req.Params.Path.Method == IntrospectionMethod {
+		return &Response{Status: 200, Body: g.schema()}, nil
+	}
+
+	method := MethodFromContext(ctx)
 	if method == nil {
-		return &Response{http.StatusNotFound, "Not Found"}, nil
+		return &Response{Status: http.StatusNotFound, Body: "Not Found"}, nil
 	}
 
 	mtype := method.Type
@@ -154,8 +287,22 @@ func (g *Gateway) Handle(event json.RawMessage, ctx *apex.Context) (interface{},
 	if mtype.NumIn() > 1 {
 		in := reflect.New(mtype.In(1).Elem())
 		args = append(args, in)
-		if err := json.Unmarshal(req.Body, in.Interface()); err != nil {
-			return &Response{http.StatusBadRequest, "Malformed Request Body"}, nil
+		codec := g.requestCodec(req.Params.Header.Get("Content-Type"))
+		if err := codec.Unmarshal(req.Body, in.Interface()); err != nil {
+			return &Response{Status: http.StatusBadRequest, Body: "Malformed Request Body"}, nil
+		}
+
+		if fields := g.validator().Validate(in.Interface()); len(fields) > 0 {
+			return &Response{Status: 422, Body: ValidationFailed{Error: "validation_failed", Fields: fields}}, nil
+		}
+
+		if v, ok := in.Interface().(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return &Response{Status: 422, Body: ValidationFailed{
+					Error:  "validation_failed",
+					Fields: []FieldError{{Rule: err.Error()}},
+				}}, nil
+			}
 		}
 	}
 
@@ -164,32 +311,27 @@ func (g *Gateway) Handle(event json.RawMessage, ctx *apex.Context) (interface{},
 
 	// no output
 	if len(out) == 0 {
-		return &Response{200, nil}, nil
+		return &Response{Status: 200}, nil
 	}
 
 	// one output: (error)
 	if len(out) == 1 {
-		err := out[0].Interface().(error)
-		if r, ok := err.(Responder); ok {
-			return &Response{r.Status(), r.Body()}, nil
+		err, _ := out[0].Interface().(error)
+		if err == nil {
+			return &Response{Status: 200}, nil
 		}
-
-		return &Response{http.StatusInternalServerError, "Internal Server Error"}, nil
+		return g.responseForError(err), nil
 	}
 
 	// two outputs: (interface{}, error)
 	if err, ok := out[1].Interface().(error); ok && err != nil {
-		if r, ok := err.(Responder); ok {
-			return &Response{r.Status(), r.Body()}, nil
-		}
-
-		return &Response{http.StatusInternalServerError, "Internal Server Error"}, nil
+		return g.responseForError(err), nil
 	}
 
 	// two outputs: (interface{}, error)
-	if r, ok := out[0].Interface().(Responder); ok {
-		return &Response{r.Status(), r.Body()}, nil
+	if r, ok := asResponder(out[0].Interface()); ok {
+		return &Response{Status: r.Status(), Body: r.Body(), Headers: r.Headers()}, nil
 	}
 
-	return &Response{200, out[0].Interface()}, nil
+	return &Response{Status: 200, Body: out[0].Interface()}, nil
 }
@@ -1,8 +1,10 @@
 package gateway
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -57,6 +59,48 @@ func event(method, body string) json.RawMessage {
 	}`)
 }
 
+func proxyEventReq(method, body string) json.RawMessage {
+	encodedBody, _ := json.Marshal(body)
+
+	return json.RawMessage(`{
+	  "httpMethod": "POST",
+	  "path": "/public/` + method + `",
+	  "pathParameters": {
+	    "method": "` + method + `"
+	  },
+	  "queryStringParameters": {},
+	  "headers": {
+	    "Content-Type": "application/json"
+	  },
+	  "body": ` + string(encodedBody) + `,
+	  "isBase64Encoded": false,
+	  "requestContext": {
+	    "accountId": "",
+	    "apiId": "whxkpa6fwf",
+	    "httpMethod": "POST",
+	    "requestId": "55066e03-19f7-11e6-8e97-231379f58d27",
+	    "resourceId": "cppmxl",
+	    "resourcePath": "/public/{method}",
+	    "stage": "prod",
+	    "identity": {
+	      "sourceIp": "70.66.179.182"
+	    }
+	  }
+	}`)
+}
+
+type ValidatedInput struct {
+	Name string `json:"name" validate:"required"`
+	Age  int    `json:"age" validate:"min=0,max=150"`
+}
+
+func (in *ValidatedInput) Validate() error {
+	if in.Name == "admin" {
+		return errors.New("name is reserved")
+	}
+	return nil
+}
+
 type Math struct{}
 
 type AddInput struct {
@@ -80,9 +124,9 @@ func (m *Math) NoInput() (int, error) {
 	return 5, nil
 }
 
-// func (m *Math) NoInputNoOutput() error {
-// 	return nil
-// }
+func (m *Math) NoInputNoOutput() error {
+	return nil
+}
 
 func (m *Math) Error(in *AddInput) (int, error) {
 	return 0, errors.New("boom")
@@ -92,6 +136,31 @@ func (m *Math) notExported(a, b int) error {
 	return nil
 }
 
+func (m *Math) Register(in *ValidatedInput) (interface{}, error) {
+	return in.Name, nil
+}
+
+func (m *Math) Fail() (int, error) {
+	return 0, NewError(400, "bad_request", "bad input")
+}
+
+// legacyErr implements the original two-method Responder contract, without Headers().
+type legacyErr struct{}
+
+func (legacyErr) Error() string     { return "legacy" }
+func (legacyErr) Status() int       { return 418 }
+func (legacyErr) Body() interface{} { return "teapot" }
+
+func (m *Math) Teapot() (int, error) {
+	return 0, legacyErr{}
+}
+
+var errNotFound = errors.New("not found")
+
+func (m *Math) Missing() (int, error) {
+	return 0, errNotFound
+}
+
 func TestNewConfig(t *testing.T) {
 	g := NewConfig(&Config{
 		Service: &Math{},
@@ -99,7 +168,7 @@ func TestNewConfig(t *testing.T) {
 	})
 
 	m := g.Methods()
-	assert.Len(t, m, 5, "incorrect number of methods")
+	assert.Len(t, m, 10, "incorrect number of methods")
 }
 
 func TestGateway_Lookup(t *testing.T) {
@@ -130,7 +199,15 @@ func TestGateway_Handle_noInput(t *testing.T) {
 	e := event("no_input", `{}`)
 	v, err := g.Handle(e, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, &Response{200, 5}, v)
+	assert.Equal(t, &Response{Status: 200, Body: 5}, v)
+}
+
+func TestGateway_Handle_noInputNoOutput(t *testing.T) {
+	g := New(&Math{})
+	e := event("no_input_no_output", `{}`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &Response{Status: 200}, v)
 }
 
 func TestGateway_Handle_lowercaseReturnInterface(t *testing.T) {
@@ -138,7 +215,7 @@ func TestGateway_Handle_lowercaseReturnInterface(t *testing.T) {
 	e := event("add", `{ "a": 5, "b": 10 }`)
 	v, err := g.Handle(e, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, &Response{200, 15}, v)
+	assert.Equal(t, &Response{Status: 200, Body: 15}, v)
 }
 
 func TestGateway_Handle_lowercaseReturn(t *testing.T) {
@@ -146,7 +223,7 @@ func TestGateway_Handle_lowercaseReturn(t *testing.T) {
 	e := event("sub", `{ "a": 10, "b": 5 }`)
 	v, err := g.Handle(e, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, &Response{200, 5}, v)
+	assert.Equal(t, &Response{Status: 200, Body: 5}, v)
 }
 
 func TestGateway_Handle_notFound(t *testing.T) {
@@ -176,6 +253,247 @@ func TestGateway_Handle_malformedRequestBody(t *testing.T) {
 	assert.Equal(t, "Malformed Request Body", v.(*Response).Body)
 }
 
+func TestGateway_Use(t *testing.T) {
+	var calls []string
+
+	track := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req *Request) (*Response, error) {
+				calls = append(calls, name)
+				return next(ctx, req)
+			}
+		}
+	}
+
+	g := NewConfig(&Config{Service: &Math{}})
+	g.Use(track("outer"), track("inner"))
+
+	e := event("add", `{ "a": 1, "b": 2 }`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &Response{Status: 200, Body: 3}, v)
+	assert.Equal(t, []string{"outer", "inner"}, calls)
+}
+
+func TestGateway_Use_shortCircuit(t *testing.T) {
+	denied := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			return &Response{Status: 403, Body: "Forbidden"}, nil
+		}
+	}
+
+	g := NewConfig(&Config{Service: &Math{}})
+	g.Use(denied)
+
+	e := event("add", `{ "a": 1, "b": 2 }`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &Response{Status: 403, Body: "Forbidden"}, v)
+}
+
+func eventWithAccept(method, body, accept string) json.RawMessage {
+	return json.RawMessage(`{
+	  "body": ` + body + `,
+	  "params": {
+	    "path": {
+	      "method": "` + method + `"
+	    },
+	    "header": {
+	      "Accept": "` + accept + `"
+	    }
+	  }
+	}`)
+}
+
+// upperCodec is a toy Codec used to exercise negotiation: it upper-cases the
+// JSON-encoded body and reports itself as "text/plain".
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.ToUpper(string(b))), nil
+}
+
+func (upperCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (upperCodec) ContentType() string {
+	return "text/plain"
+}
+
+func (upperCodec) Accepts(accept string) bool {
+	return strings.Contains(accept, "text/plain")
+}
+
+func TestGateway_Handle_codecNegotiation(t *testing.T) {
+	g := NewConfig(&Config{
+		Service: &Math{},
+		Codecs:  []Codec{upperCodec{}},
+	})
+
+	e := eventWithAccept("sub", `{ "a": 10, "b": 5 }`, "text/plain")
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+
+	res := v.(*Response)
+	assert.Equal(t, 200, res.Status)
+	assert.Equal(t, "5", string(res.Body.(encodedBody)))
+	assert.Equal(t, "text/plain", res.Headers["Content-Type"])
+}
+
+func TestGateway_Handle_proxyFormat(t *testing.T) {
+	g := New(&Math{})
+	e := proxyEventReq("add", `{ "a": 5, "b": 10 }`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &proxyResponse{StatusCode: 200, Body: "15"}, v)
+}
+
+func TestGateway_Handle_proxyFormat_notFound(t *testing.T) {
+	g := New(&Math{})
+	e := proxyEventReq("nothing", `{}`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &proxyResponse{StatusCode: 404, Body: `"Not Found"`}, v)
+}
+
+func TestGateway_Handle_proxyFormat_malformedBody(t *testing.T) {
+	g := New(&Math{})
+	e := json.RawMessage(`{
+	  "httpMethod": "POST",
+	  "pathParameters": { "method": "add" },
+	  "body": "not-valid-base64!!",
+	  "isBase64Encoded": true
+	}`)
+
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &proxyResponse{StatusCode: 400, Body: `"Malformed Request"`}, v)
+}
+
+func TestGateway_Handle_introspection(t *testing.T) {
+	g := NewConfig(&Config{
+		Service:   &Math{},
+		MethodDoc: map[string]string{"Add": "Add two numbers."},
+	})
+
+	e := event("__schema", `{}`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+
+	res := v.(*Response)
+	assert.Equal(t, 200, res.Status)
+
+	schema := res.Body.(*Schema)
+	assert.Len(t, schema.Methods, 10)
+
+	var add *MethodSchema
+	for i := range schema.Methods {
+		if schema.Methods[i].Name == "Add" {
+			add = &schema.Methods[i]
+		}
+	}
+
+	assert.NotNil(t, add)
+	assert.Equal(t, "add", add.SnakeName)
+	assert.Equal(t, "Add two numbers.", add.Doc)
+	assert.Equal(t, "gateway.AddInput", add.Input.Type)
+	assert.Equal(t, 2, len(add.Input.Fields))
+}
+
+func TestGateway_Handle_introspection_disabled(t *testing.T) {
+	g := NewConfig(&Config{
+		Service:              &Math{},
+		DisableIntrospection: true,
+	})
+
+	e := event("__schema", `{}`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, v.(*Response).Status)
+}
+
+func TestGateway_Handle_validationFailed(t *testing.T) {
+	g := New(&Math{})
+	e := event("register", `{ "age": 200 }`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+
+	res := v.(*Response)
+	assert.Equal(t, 422, res.Status)
+
+	body := res.Body.(ValidationFailed)
+	assert.Equal(t, "validation_failed", body.Error)
+	assert.Equal(t, []FieldError{
+		{Field: "name", Rule: "required"},
+		{Field: "age", Rule: "max=150"},
+	}, body.Fields)
+}
+
+func TestGateway_Handle_validationHook(t *testing.T) {
+	g := New(&Math{})
+	e := event("register", `{ "name": "admin", "age": 10 }`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+
+	res := v.(*Response)
+	assert.Equal(t, 422, res.Status)
+
+	body := res.Body.(ValidationFailed)
+	assert.Equal(t, []FieldError{{Rule: "name is reserved"}}, body.Fields)
+}
+
+func TestGateway_Handle_validationPasses(t *testing.T) {
+	g := New(&Math{})
+	e := event("register", `{ "name": "tj", "age": 30 }`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &Response{Status: 200, Body: "tj"}, v)
+}
+
+func TestGateway_Handle_httpError(t *testing.T) {
+	g := New(&Math{})
+	e := event("fail", `{}`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+
+	res := v.(*Response)
+	assert.Equal(t, 400, res.Status)
+	assert.Equal(t, struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}{"bad_request", "bad input"}, res.Body)
+}
+
+func TestGateway_Handle_legacyResponder(t *testing.T) {
+	g := New(&Math{})
+	e := event("teapot", `{}`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &Response{Status: 418, Body: "teapot"}, v)
+}
+
+func TestGateway_Handle_errorMapper(t *testing.T) {
+	g := NewConfig(&Config{
+		Service: &Math{},
+		ErrorMapper: func(err error) Responder {
+			if err == errNotFound {
+				return NewError(404, "not_found", err.Error())
+			}
+			return nil
+		},
+	})
+
+	e := event("missing", `{}`)
+	v, err := g.Handle(e, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, v.(*Response).Status)
+}
+
 func TestGateway_Handle_errors(t *testing.T) {
 	g := New(&Math{})
 	e := event("error", `{ "a": 5, "b": 5 }`)
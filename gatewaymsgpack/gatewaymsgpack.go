@@ -0,0 +1,45 @@
+// Package gatewaymsgpack provides a gateway.Codec that marshals and
+// unmarshals request/response bodies as MessagePack, for registration on
+// Config.Codecs alongside the built-in JSON codec.
+package gatewaymsgpack
+
+import (
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/tj/go-gateway"
+)
+
+// contentType is the MIME type this codec produces and matches requests against.
+const contentType = "application/msgpack"
+
+// Codec implements gateway.Codec for MessagePack.
+type Codec struct{}
+
+// New returns a MessagePack Codec.
+func New() Codec {
+	return Codec{}
+}
+
+// Marshal implements gateway.Codec.
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Unmarshal implements gateway.Codec.
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// ContentType implements gateway.Codec.
+func (Codec) ContentType() string {
+	return contentType
+}
+
+// Accepts implements gateway.Codec.
+func (Codec) Accepts(accept string) bool {
+	return strings.Contains(accept, contentType)
+}
+
+var _ gateway.Codec = Codec{}
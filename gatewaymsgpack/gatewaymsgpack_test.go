@@ -0,0 +1,33 @@
+package gatewaymsgpack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type person struct {
+	Name string `msgpack:"name"`
+	Age  int    `msgpack:"age"`
+}
+
+func TestCodec_roundTrip(t *testing.T) {
+	c := New()
+
+	data, err := c.Marshal(person{Name: "Tobi", Age: 2})
+	assert.NoError(t, err)
+
+	var got person
+	assert.NoError(t, c.Unmarshal(data, &got))
+	assert.Equal(t, person{Name: "Tobi", Age: 2}, got)
+}
+
+func TestCodec_ContentType(t *testing.T) {
+	assert.Equal(t, "application/msgpack", New().ContentType())
+}
+
+func TestCodec_Accepts(t *testing.T) {
+	c := New()
+	assert.True(t, c.Accepts("application/msgpack"))
+	assert.False(t, c.Accepts("application/json"))
+}
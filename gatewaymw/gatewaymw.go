@@ -0,0 +1,78 @@
+// Package gatewaymw provides a handful of commonly used gateway.Middleware
+// implementations.
+package gatewaymw
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/tj/go-gateway"
+)
+
+// context key type, unexported to avoid collisions with other packages.
+type contextKey int
+
+// requestIDKey is the context key under which the propagated request id is stored.
+const requestIDKey contextKey = iota
+
+// Recover returns middleware that recovers from panics in downstream
+// handlers, logging the panic and responding with a 500 instead of crashing
+// the invocation.
+func Recover() gateway.Middleware {
+	return func(next gateway.Handler) gateway.Handler {
+		return func(ctx context.Context, req *gateway.Request) (res *gateway.Response, err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					log.Printf("gatewaymw: recovered from panic: %v", v)
+					res = &gateway.Response{Status: http.StatusInternalServerError, Body: "Internal Server Error"}
+					err = nil
+				}
+			}()
+
+			return next(ctx, req)
+		}
+	}
+}
+
+// AccessLog returns middleware that logs the method, response status and
+// duration of every request.
+func AccessLog() gateway.Middleware {
+	return func(next gateway.Handler) gateway.Handler {
+		return func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+			start := time.Now()
+			res, err := next(ctx, req)
+
+			status := 0
+			if res != nil {
+				status = res.Status
+			}
+
+			log.Printf("gatewaymw: %s %d %s", req.Params.Path.Method, status, time.Since(start))
+			return res, err
+		}
+	}
+}
+
+// RequestID returns middleware that propagates the API Gateway request id
+// (Request.Context.RequestID) onto ctx, making it available to downstream
+// handlers and middleware via RequestIDFromContext.
+func RequestID() gateway.Middleware {
+	return func(next gateway.Handler) gateway.Handler {
+		return func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+			if req.Context != nil && req.Context.RequestID != "" {
+				ctx = context.WithValue(ctx, requestIDKey, req.Context.RequestID)
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
+
+// RequestIDFromContext returns the request id propagated by RequestID, or ""
+// if none is present.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
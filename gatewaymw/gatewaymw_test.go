@@ -0,0 +1,72 @@
+package gatewaymw
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tj/go-gateway"
+)
+
+func TestRecover_panic(t *testing.T) {
+	h := Recover()(func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+		panic("boom")
+	})
+
+	res, err := h(context.Background(), &gateway.Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, &gateway.Response{Status: http.StatusInternalServerError, Body: "Internal Server Error"}, res)
+}
+
+func TestRecover_noPanic(t *testing.T) {
+	h := Recover()(func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+		return &gateway.Response{Status: 200, Body: "ok"}, nil
+	})
+
+	res, err := h(context.Background(), &gateway.Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, &gateway.Response{Status: 200, Body: "ok"}, res)
+}
+
+func TestRequestID_propagates(t *testing.T) {
+	var got string
+
+	h := RequestID()(func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+		got = RequestIDFromContext(ctx)
+		return &gateway.Response{Status: 200}, nil
+	})
+
+	req := &gateway.Request{Context: &gateway.Context{RequestID: "55066e03-19f7-11e6-8e97-231379f58d27"}}
+	_, err := h(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "55066e03-19f7-11e6-8e97-231379f58d27", got)
+}
+
+func TestRequestID_absent(t *testing.T) {
+	var got string
+
+	h := RequestID()(func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+		got = RequestIDFromContext(ctx)
+		return &gateway.Response{Status: 200}, nil
+	})
+
+	_, err := h(context.Background(), &gateway.Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, "", got)
+}
+
+func TestRequestIDFromContext_noValue(t *testing.T) {
+	assert.Equal(t, "", RequestIDFromContext(context.Background()))
+}
+
+func TestAccessLog(t *testing.T) {
+	h := AccessLog()(func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+		return &gateway.Response{Status: 200}, nil
+	})
+
+	res, err := h(context.Background(), &gateway.Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.Status)
+}
@@ -0,0 +1,130 @@
+// Package gatewayotel instruments a gateway.Gateway with OpenTelemetry
+// tracing and metrics via gateway.Middleware, so Lambda invocations
+// participate in end-to-end distributed traces alongside downstream
+// services.
+package gatewayotel
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tj/go-gateway"
+)
+
+// Config configures the OpenTelemetry middleware. Tracer and Meter are both
+// optional; instrumentation is skipped for whichever is nil.
+type Config struct {
+	Tracer trace.Tracer
+	Meter  metric.Meter
+
+	// Propagator extracts trace context from the request headers. Defaults
+	// to W3C Trace Context + Baggage. Set explicitly rather than relying on
+	// otel.SetTextMapPropagator, which this package does not call into.
+	Propagator propagation.TextMapPropagator
+}
+
+// headerCarrier adapts a gateway.Header to propagation.TextMapCarrier,
+// looking up keys case-insensitively via Header.Get rather than the exact
+// casing the propagator requests (e.g. "traceparent").
+type headerCarrier gateway.Header
+
+func (h headerCarrier) Get(key string) string {
+	return gateway.Header(h).Get(key)
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = value
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Middleware returns gateway middleware that, per invocation: starts a span
+// named after the resolved method, linked to any upstream trace propagated
+// via W3C traceparent/tracestate headers on the request; and records
+// request count, duration and error count metrics.
+func Middleware(cfg Config) gateway.Middleware {
+	propagator := cfg.Propagator
+	if propagator == nil {
+		propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+
+	var (
+		requestCount metric.Int64Counter
+		errorCount   metric.Int64Counter
+		duration     metric.Float64Histogram
+	)
+
+	if cfg.Meter != nil {
+		requestCount, _ = cfg.Meter.Int64Counter("gateway.request.count")
+		errorCount, _ = cfg.Meter.Int64Counter("gateway.request.error_count")
+		duration, _ = cfg.Meter.Float64Histogram("gateway.request.duration")
+	}
+
+	return func(next gateway.Handler) gateway.Handler {
+		return func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+			name := req.Params.Path.Method
+			if m := gateway.MethodFromContext(ctx); m != nil {
+				name = m.Name
+			}
+
+			if cfg.Tracer != nil {
+				ctx = propagator.Extract(ctx, headerCarrier(req.Params.Header))
+
+				var span trace.Span
+				ctx, span = cfg.Tracer.Start(ctx, name)
+				defer span.End()
+			}
+
+			start := time.Now()
+			res, err := next(ctx, req)
+
+			status := 0
+			if res != nil {
+				status = res.Status
+			}
+
+			attrs := []attribute.KeyValue{
+				attribute.String("rpc.method", name),
+				attribute.Int("http.status_code", status),
+			}
+
+			if span := trace.SpanFromContext(ctx); span.IsRecording() {
+				span.SetAttributes(attrs...)
+				if req.Context != nil {
+					span.SetAttributes(
+						attribute.String("gateway.request_id", req.Context.RequestID),
+						attribute.String("net.peer.ip", req.Context.SourceIP),
+						attribute.String("gateway.stage", req.Context.Stage),
+					)
+				}
+				if err != nil || status >= http.StatusInternalServerError {
+					span.RecordError(err)
+				}
+			}
+
+			if requestCount != nil {
+				requestCount.Add(ctx, 1, metric.WithAttributes(attrs...))
+			}
+			if duration != nil {
+				duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+			}
+			if errorCount != nil && status >= http.StatusBadRequest {
+				errorCount.Add(ctx, 1, metric.WithAttributes(attrs...))
+			}
+
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package gatewayotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/tj/go-gateway"
+)
+
+func TestMiddleware_span(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	mw := Middleware(Config{Tracer: tp.Tracer("gatewayotel_test")})
+
+	h := mw(func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+		return &gateway.Response{Status: 200}, nil
+	})
+
+	req := &gateway.Request{
+		Context: &gateway.Context{RequestID: "req-1", SourceIP: "1.2.3.4", Stage: "prod"},
+	}
+	req.Params.Path.Method = "Add"
+
+	_, err := h(context.Background(), req)
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "Add", spans[0].Name)
+
+	attrs := spans[0].Attributes
+	assert.Contains(t, attrs, attribute.String("rpc.method", "Add"))
+	assert.Contains(t, attrs, attribute.Int("http.status_code", 200))
+	assert.Contains(t, attrs, attribute.String("gateway.request_id", "req-1"))
+	assert.Contains(t, attrs, attribute.String("net.peer.ip", "1.2.3.4"))
+	assert.Contains(t, attrs, attribute.String("gateway.stage", "prod"))
+}
+
+func TestMiddleware_span_recordsError(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	mw := Middleware(Config{Tracer: tp.Tracer("gatewayotel_test")})
+
+	boom := errors.New("boom")
+	h := mw(func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+		return nil, boom
+	})
+
+	_, err := h(context.Background(), &gateway.Request{})
+	assert.Equal(t, boom, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.NotEmpty(t, spans[0].Events)
+}
+
+// TestMiddleware_extractsCaseInsensitiveTraceparent is a regression test:
+// propagation must go through gateway.Header.Get (case-insensitive), not a
+// raw map lookup, or an upstream trace sent with non-canonical header casing
+// (as API Gateway and many clients send it) never gets linked.
+func TestMiddleware_extractsCaseInsensitiveTraceparent(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	mw := Middleware(Config{Tracer: tp.Tracer("gatewayotel_test")})
+
+	h := mw(func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+		return &gateway.Response{Status: 200}, nil
+	})
+
+	req := &gateway.Request{}
+	req.Params.Header = gateway.Header{
+		"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+
+	_, err := h(context.Background(), req)
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.True(t, spans[0].Parent.IsRemote())
+}
+
+func TestMiddleware_metrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	mw := Middleware(Config{Meter: mp.Meter("gatewayotel_test")})
+
+	h := mw(func(ctx context.Context, req *gateway.Request) (*gateway.Response, error) {
+		return &gateway.Response{Status: 500}, nil
+	})
+	req := &gateway.Request{}
+	req.Params.Path.Method = "Add"
+
+	_, err := h(context.Background(), req)
+	assert.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+
+	names := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names[m.Name] = true
+		}
+	}
+
+	assert.True(t, names["gateway.request.count"])
+	assert.True(t, names["gateway.request.error_count"])
+	assert.True(t, names["gateway.request.duration"])
+}
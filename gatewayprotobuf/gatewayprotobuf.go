@@ -0,0 +1,56 @@
+// Package gatewayprotobuf provides a gateway.Codec that marshals and
+// unmarshals request/response bodies as protocol buffers, for registration
+// on Config.Codecs alongside the built-in JSON codec. Values passed to
+// Marshal, and the destination passed to Unmarshal, must implement
+// proto.Message.
+package gatewayprotobuf
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tj/go-gateway"
+)
+
+// contentType is the MIME type this codec produces and matches requests against.
+const contentType = "application/protobuf"
+
+// Codec implements gateway.Codec for protocol buffers.
+type Codec struct{}
+
+// New returns a protocol buffers Codec.
+func New() Codec {
+	return Codec{}
+}
+
+// Marshal implements gateway.Codec. v must implement proto.Message.
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("gatewayprotobuf: %T does not implement proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal implements gateway.Codec. v must implement proto.Message.
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("gatewayprotobuf: %T does not implement proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// ContentType implements gateway.Codec.
+func (Codec) ContentType() string {
+	return contentType
+}
+
+// Accepts implements gateway.Codec.
+func (Codec) Accepts(accept string) bool {
+	return strings.Contains(accept, contentType)
+}
+
+var _ gateway.Codec = Codec{}
@@ -0,0 +1,34 @@
+package gatewayprotobuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestCodec_roundTrip(t *testing.T) {
+	c := New()
+
+	data, err := c.Marshal(wrapperspb.String("hello"))
+	assert.NoError(t, err)
+
+	got := &wrapperspb.StringValue{}
+	assert.NoError(t, c.Unmarshal(data, got))
+	assert.Equal(t, "hello", got.GetValue())
+}
+
+func TestCodec_Marshal_notAMessage(t *testing.T) {
+	_, err := New().Marshal("not a proto.Message")
+	assert.Error(t, err)
+}
+
+func TestCodec_ContentType(t *testing.T) {
+	assert.Equal(t, "application/protobuf", New().ContentType())
+}
+
+func TestCodec_Accepts(t *testing.T) {
+	c := New()
+	assert.True(t, c.Accepts("application/protobuf"))
+	assert.False(t, c.Accepts("application/json"))
+}
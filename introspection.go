@@ -0,0 +1,140 @@
+package gateway
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// IntrospectionMethod is the reserved method name that returns a Schema
+// describing every method registered on the Gateway, unless
+// Config.DisableIntrospection is set.
+const IntrospectionMethod = "__schema"
+
+// Schema describes the methods exposed by a Gateway.
+type Schema struct {
+	Methods []MethodSchema `json:"methods"`
+}
+
+// MethodSchema describes a single registered method.
+type MethodSchema struct {
+	Name      string      `json:"name"`       // CamelCase method name
+	SnakeName string      `json:"snake_name"` // snake_case method name, as used in requests
+	Doc       string      `json:"doc,omitempty"`
+	Input     *TypeSchema `json:"input,omitempty"`
+	Output    *TypeSchema `json:"output,omitempty"`
+}
+
+// TypeSchema describes an input or output struct type.
+type TypeSchema struct {
+	Type   string        `json:"type"`
+	Fields []FieldSchema `json:"fields,omitempty"`
+}
+
+// FieldSchema describes a single field of a TypeSchema.
+type FieldSchema struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+}
+
+// schema builds a Schema describing every method registered on g.
+func (g *Gateway) schema() *Schema {
+	methods := g.Methods()
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].Name < methods[j].Name
+	})
+
+	s := &Schema{}
+	for _, m := range methods {
+		ms := MethodSchema{
+			Name:      m.Name,
+			SnakeName: camelToSnake(m.Name),
+			Doc:       g.MethodDoc[m.Name],
+		}
+
+		mtype := m.Type
+		if mtype.NumIn() > 1 {
+			ms.Input = typeSchemaFor(mtype.In(1))
+		}
+		if mtype.NumOut() == 2 {
+			ms.Output = typeSchemaFor(mtype.Out(0))
+		}
+
+		s.Methods = append(s.Methods, ms)
+	}
+
+	return s
+}
+
+// typeSchemaFor describes `t`, dereferencing pointers and, for structs,
+// describing each exported field using its JSON tag.
+func typeSchemaFor(t reflect.Type) *TypeSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	ts := &TypeSchema{Type: t.String()}
+	if t.Kind() != reflect.Struct {
+		return ts
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+
+		name, omitempty := jsonFieldName(f)
+
+		ts.Fields = append(ts.Fields, FieldSchema{
+			Name:     name,
+			Type:     f.Type.String(),
+			Required: !omitempty,
+		})
+	}
+
+	return ts
+}
+
+// jsonFieldName returns the name `f` is encoded under per its `json` tag
+// (falling back to the Go field name) and whether it carries `omitempty`.
+func jsonFieldName(f reflect.StructField) (name string, omitempty bool) {
+	name = f.Name
+
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return
+	}
+
+	parts := strings.Split(tag, ",")
+	if parts[0] != "" && parts[0] != "-" {
+		name = parts[0]
+	}
+	for _, p := range parts[1:] {
+		if p == "omitempty" {
+			omitempty = true
+		}
+	}
+
+	return
+}
+
+// camelToSnake converts a CamelCase method name to the snake_case form
+// accepted by Gateway.Lookup.
+func camelToSnake(s string) string {
+	var b strings.Builder
+
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r - 'A' + 'a')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
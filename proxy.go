@@ -0,0 +1,157 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// proxyEvent is the API Gateway (or ALB) Lambda Proxy Integration event shape.
+type proxyEvent struct {
+	HTTPMethod            string              `json:"httpMethod"`
+	Path                  string              `json:"path"`
+	PathParameters        map[string]string   `json:"pathParameters"`
+	QueryStringParameters map[string]string   `json:"queryStringParameters"`
+	Headers               Header              `json:"headers"`
+	Body                  string              `json:"body"`
+	IsBase64Encoded       bool                `json:"isBase64Encoded"`
+	RequestContext        proxyRequestContext `json:"requestContext"`
+}
+
+// proxyRequestContext is the `requestContext` object of a proxy event.
+type proxyRequestContext struct {
+	AccountID    string `json:"accountId"`
+	APIID        string `json:"apiId"`
+	HTTPMethod   string `json:"httpMethod"`
+	RequestID    string `json:"requestId"`
+	ResourceID   string `json:"resourceId"`
+	ResourcePath string `json:"resourcePath"`
+	Stage        string `json:"stage"`
+	Identity     struct {
+		APIKey                        string `json:"apiKey"`
+		Caller                        string `json:"caller"`
+		CognitoAuthenticationProvider string `json:"cognitoAuthenticationProvider"`
+		CognitoAuthenticationType     string `json:"cognitoAuthenticationType"`
+		CognitoIdentityID             string `json:"cognitoIdentityId"`
+		CognitoIdentityPoolID         string `json:"cognitoIdentityPoolId"`
+		SourceIP                      string `json:"sourceIp"`
+		User                          string `json:"user"`
+		UserAgent                     string `json:"userAgent"`
+		UserArn                       string `json:"userArn"`
+	} `json:"identity"`
+}
+
+// toContext converts a proxy event's requestContext into our Context type.
+func (rc proxyRequestContext) toContext() *Context {
+	return &Context{
+		AccountID:                     rc.AccountID,
+		APIID:                         rc.APIID,
+		APIKey:                        rc.Identity.APIKey,
+		Caller:                        rc.Identity.Caller,
+		CognitoAuthenticationProvider: rc.Identity.CognitoAuthenticationProvider,
+		CognitoAuthenticationType:     rc.Identity.CognitoAuthenticationType,
+		CognitoIdentityID:             rc.Identity.CognitoIdentityID,
+		CognitoIdentityPoolID:         rc.Identity.CognitoIdentityPoolID,
+		HTTPMethod:                    rc.HTTPMethod,
+		RequestID:                     rc.RequestID,
+		ResourceID:                    rc.ResourceID,
+		ResourcePath:                  rc.ResourcePath,
+		SourceIP:                      rc.Identity.SourceIP,
+		Stage:                         rc.Stage,
+		User:                          rc.Identity.User,
+		UserAgent:                     rc.Identity.UserAgent,
+		UserArn:                       rc.Identity.UserArn,
+	}
+}
+
+// proxyResponse is the Lambda Proxy Integration response shape.
+type proxyResponse struct {
+	StatusCode      int    `json:"statusCode"`
+	Headers         Header `json:"headers,omitempty"`
+	Body            string `json:"body"`
+	IsBase64Encoded bool   `json:"isBase64Encoded,omitempty"`
+}
+
+// toProxy converts a Response to the Lambda Proxy Integration response shape,
+// encoding the body as the spec requires it to be a string. A body already
+// encoded by a negotiated Codec (see negotiateResponseCodec) is used as-is;
+// anything else is JSON-encoded.
+func (r *Response) toProxy() *proxyResponse {
+	var body string
+	if b, ok := r.Body.(encodedBody); ok {
+		body = string(b)
+	} else {
+		raw, _ := json.Marshal(r.Body)
+		body = string(raw)
+	}
+
+	return &proxyResponse{
+		StatusCode: r.Status,
+		Headers:    r.Headers,
+		Body:       body,
+	}
+}
+
+// detectEventFormat sniffs the event format from the keys present in `event`.
+func detectEventFormat(event json.RawMessage) EventFormat {
+	var probe struct {
+		HTTPMethod string `json:"httpMethod"`
+	}
+
+	if json.Unmarshal(event, &probe) == nil && probe.HTTPMethod != "" {
+		return FormatProxy
+	}
+
+	return FormatPassthrough
+}
+
+// decodeRequest decodes `event` into a Request, according to `g.EventFormat`
+// (auto-detecting when FormatAuto). It also reports whether the event was in
+// proxy format, so Handle knows which shape to respond with.
+func (g *Gateway) decodeRequest(event json.RawMessage) (*Request, bool, error) {
+	format := g.EventFormat
+	if format == FormatAuto {
+		format = detectEventFormat(event)
+	}
+
+	if format == FormatProxy {
+		return g.decodeProxyRequest(event)
+	}
+
+	var req Request
+	if err := json.Unmarshal(event, &req); err != nil {
+		return nil, false, err
+	}
+
+	return &req, false, nil
+}
+
+// decodeProxyRequest decodes a Lambda Proxy Integration event into a Request.
+func (g *Gateway) decodeProxyRequest(event json.RawMessage) (*Request, bool, error) {
+	var pe proxyEvent
+	if err := json.Unmarshal(event, &pe); err != nil {
+		return nil, true, err
+	}
+
+	body := []byte(pe.Body)
+	if pe.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(pe.Body)
+		if err != nil {
+			return nil, true, err
+		}
+		body = decoded
+	}
+
+	pathParam := g.PathParam
+	if pathParam == "" {
+		pathParam = "method"
+	}
+
+	req := &Request{
+		Body:    json.RawMessage(body),
+		Context: pe.RequestContext.toContext(),
+	}
+	req.Params.Path.Method = pe.PathParameters[pathParam]
+	req.Params.Header = pe.Headers
+
+	return req, true, nil
+}
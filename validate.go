@@ -0,0 +1,120 @@
+package gateway
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes a single field that failed validation.
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+}
+
+// ValidationFailed is the response body returned when validation fails.
+type ValidationFailed struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
+}
+
+// Validator validates a decoded input value, returning the fields that
+// failed validation (nil/empty if it's valid).
+type Validator interface {
+	Validate(v interface{}) []FieldError
+}
+
+// defaultValidator validates `validate:"required,min=0,max=10"`-style struct
+// tags.
+type defaultValidator struct{}
+
+func (defaultValidator) Validate(v interface{}) []FieldError {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs []FieldError
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		name, _ := jsonFieldName(field)
+		fv := rv.Field(i)
+
+		for _, rule := range strings.Split(tag, ",") {
+			rule = strings.TrimSpace(rule)
+			if rule == "" {
+				continue
+			}
+
+			ruleName, arg := rule, ""
+			if idx := strings.Index(rule, "="); idx >= 0 {
+				ruleName, arg = rule[:idx], rule[idx+1:]
+			}
+
+			if !validateRule(ruleName, arg, fv) {
+				errs = append(errs, FieldError{Field: name, Rule: rule})
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateRule reports whether `fv` satisfies the named rule.
+func validateRule(rule, arg string, fv reflect.Value) bool {
+	switch rule {
+	case "required":
+		return !fv.IsZero()
+	case "min":
+		return compareBound(arg, fv, func(n, bound float64) bool { return n >= bound })
+	case "max":
+		return compareBound(arg, fv, func(n, bound float64) bool { return n <= bound })
+	default:
+		// Unknown rules are ignored rather than rejecting the request.
+		return true
+	}
+}
+
+// compareBound compares `fv` (a string's length or a number's value) against
+// the bound encoded in `arg`, using `cmp`.
+func compareBound(arg string, fv reflect.Value, cmp func(n, bound float64) bool) bool {
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return true
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return cmp(float64(len(fv.String())), bound)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp(float64(fv.Int()), bound)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp(float64(fv.Uint()), bound)
+	case reflect.Float32, reflect.Float64:
+		return cmp(fv.Float(), bound)
+	default:
+		return true
+	}
+}
+
+// validator returns the configured Validator, falling back to defaultValidator.
+func (g *Gateway) validator() Validator {
+	if g.Validator != nil {
+		return g.Validator
+	}
+	return defaultValidator{}
+}